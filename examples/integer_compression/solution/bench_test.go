@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// benchN is the number of values generated for each synthetic distribution.
+const benchN = 1 << 16
+
+// benchDistributions generates the synthetic uint32 sequences used to
+// benchmark every codec under a range of realistic data shapes.
+var benchDistributions = map[string]func(n int) []uint32{
+	"all-zeros":         genAllZeros,
+	"dense-ascending":   genDenseAscending,
+	"sparse-sorted-ids": genSparseSortedIDs,
+	"uniform-random":    genUniformRandom,
+	"zipf":              genZipf,
+	"block-repeating":   genBlockRepeating,
+}
+
+func genAllZeros(n int) []uint32 {
+	return make([]uint32, n)
+}
+
+func genDenseAscending(n int) []uint32 {
+	out := make([]uint32, n)
+	for i := range out {
+		out[i] = uint32(i)
+	}
+	return out
+}
+
+// genSparseSortedIDs mimics a posting list: sorted, unique IDs with gaps.
+func genSparseSortedIDs(n int) []uint32 {
+	r := rand.New(rand.NewSource(1))
+	out := make([]uint32, n)
+	var v uint32
+	for i := range out {
+		v += uint32(r.Intn(1000) + 1)
+		out[i] = v
+	}
+	return out
+}
+
+func genUniformRandom(n int) []uint32 {
+	r := rand.New(rand.NewSource(2))
+	out := make([]uint32, n)
+	for i := range out {
+		out[i] = r.Uint32()
+	}
+	return out
+}
+
+func genZipf(n int) []uint32 {
+	r := rand.New(rand.NewSource(3))
+	z := rand.NewZipf(r, 1.5, 1, 1<<20)
+	out := make([]uint32, n)
+	for i := range out {
+		out[i] = uint32(z.Uint64())
+	}
+	return out
+}
+
+// genBlockRepeating is "not-so-random": a short block tiled across the
+// whole sequence, so byte-level compressors can't treat it as uniform.
+func genBlockRepeating(n int) []uint32 {
+	block := []uint32{17, 42, 9, 256, 1, 1000, 3, 8}
+	out := make([]uint32, n)
+	for i := range out {
+		out[i] = block[i%len(block)]
+	}
+	return out
+}
+
+func BenchmarkCompress(b *testing.B) {
+	for distName, gen := range benchDistributions {
+		data := gen(benchN)
+		for codecName := range codecsByName {
+			b.Run(distName+"/"+codecName, func(b *testing.B) {
+				b.SetBytes(int64(len(data) * 4))
+				for i := 0; i < b.N; i++ {
+					if _, err := Compress(data, codecName); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkDecompress(b *testing.B) {
+	for distName, gen := range benchDistributions {
+		data := gen(benchN)
+		for codecName := range codecsByName {
+			compressed, err := Compress(data, codecName)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(distName+"/"+codecName, func(b *testing.B) {
+				b.SetBytes(int64(len(data) * 4))
+				for i := 0; i < b.N; i++ {
+					if _, err := Decompress(compressed); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import "fmt"
+
+// bitWriter packs values of arbitrary bit width into a byte slice,
+// least-significant bits first, filling bytes low-to-high as they fill.
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	bits int
+}
+
+func newBitWriter() *bitWriter {
+	return &bitWriter{}
+}
+
+func (w *bitWriter) writeBits(v uint64, width int) {
+	w.acc |= v << uint(w.bits)
+	w.bits += width
+	for w.bits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.bits -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.bits > 0 {
+		return append(w.buf, byte(w.acc))
+	}
+	return w.buf
+}
+
+// bitReader reads back values written by bitWriter, in the same order.
+type bitReader struct {
+	buf  []byte
+	pos  int
+	acc  uint64
+	bits int
+}
+
+func newBitReader(buf []byte) *bitReader {
+	return &bitReader{buf: buf}
+}
+
+func (r *bitReader) readBits(width int) (uint64, error) {
+	for r.bits < width {
+		if r.pos >= len(r.buf) {
+			return 0, fmt.Errorf("bitpack: unexpected end of stream")
+		}
+		r.acc |= uint64(r.buf[r.pos]) << uint(r.bits)
+		r.pos++
+		r.bits += 8
+	}
+	mask := uint64(1)<<uint(width) - 1
+	v := r.acc & mask
+	r.acc >>= uint(width)
+	r.bits -= width
+	return v, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const codecDeltaVarintID = 1
+
+func init() {
+	RegisterCodec(codecDeltaVarintID, "delta-varint", deltaVarintCodec{})
+}
+
+// deltaVarintCodec stores the first value as-is, then the delta to the
+// previous value for every subsequent one, each signed-varint packed. It's
+// cheap and effective on sorted or slowly-changing sequences.
+type deltaVarintCodec struct{}
+
+func (deltaVarintCodec) Compress(data []uint32) []byte {
+	buf := make([]byte, 0, len(data)*2)
+	var tmp [binary.MaxVarintLen64]byte
+	var prev int64
+	for i, v := range data {
+		val := int64(v)
+		delta := val
+		if i > 0 {
+			delta = val - prev
+		}
+		prev = val
+		n := binary.PutVarint(tmp[:], delta)
+		buf = append(buf, tmp[:n]...)
+	}
+	return buf
+}
+
+// Estimate sums the varint length each delta would take, without actually
+// encoding them.
+func (deltaVarintCodec) Estimate(data []uint32) float64 {
+	if len(data) == 0 {
+		return 1.0
+	}
+	var totalBytes int
+	var prev int64
+	for i, v := range data {
+		val := int64(v)
+		delta := val
+		if i > 0 {
+			delta = val - prev
+		}
+		prev = val
+		totalBytes += varintLen(delta)
+	}
+	return float64(len(data)*4) / float64(totalBytes)
+}
+
+// varintLen returns the number of bytes binary.PutVarint would use to
+// encode v, without actually encoding it. It mirrors PutVarint's own
+// zigzag encoding.
+func varintLen(v int64) int {
+	u := uint64(v) << 1
+	if v < 0 {
+		u = ^u
+	}
+	n := 1
+	for u >= 0x80 {
+		u >>= 7
+		n++
+	}
+	return n
+}
+
+func (deltaVarintCodec) Decompress(data []byte) ([]uint32, error) {
+	var result []uint32
+	var prev int64
+	offset := 0
+	for offset < len(data) {
+		delta, n := binary.Varint(data[offset:])
+		if n <= 0 {
+			return nil, fmt.Errorf("delta-varint: malformed varint at offset %d", offset)
+		}
+		offset += n
+
+		v := delta
+		if len(result) > 0 {
+			v = prev + delta
+		}
+		if v < 0 || v > int64(^uint32(0)) {
+			return nil, fmt.Errorf("delta-varint: decoded value %d out of uint32 range", v)
+		}
+		prev = v
+		result = append(result, uint32(v))
+	}
+	return result, nil
+}
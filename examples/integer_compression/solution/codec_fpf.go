@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+const codecFrameOfReferenceID = 2
+
+func init() {
+	RegisterCodec(codecFrameOfReferenceID, "fpf", frameOfReferenceCodec{})
+}
+
+// frameOfReferenceCodec stores every value as an offset from the block's
+// minimum, bit-packed at a common width chosen to cover the 90th percentile
+// of those offsets. Offsets that don't fit ("exceptions") are patched in
+// separately. This is the PFOR (patched frame-of-reference) scheme used by
+// FastPFOR.
+type frameOfReferenceCodec struct{}
+
+// bitsNeeded returns how many bits it takes to represent v, 0 for v == 0.
+func bitsNeeded(v uint32) int {
+	n := 0
+	for v > 0 {
+		n++
+		v >>= 1
+	}
+	return n
+}
+
+func (frameOfReferenceCodec) Compress(data []uint32) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	out := make([]byte, 0, len(data))
+	n := binary.PutUvarint(tmp[:], uint64(len(data)))
+	out = append(out, tmp[:n]...)
+	if len(data) == 0 {
+		return out
+	}
+
+	min := data[0]
+	for _, v := range data {
+		if v < min {
+			min = v
+		}
+	}
+	var minBuf [4]byte
+	binary.LittleEndian.PutUint32(minBuf[:], min)
+	out = append(out, minBuf[:]...)
+
+	deltas := make([]uint32, len(data))
+	widths := make([]int, len(data))
+	for i, v := range data {
+		deltas[i] = v - min
+		widths[i] = bitsNeeded(deltas[i])
+	}
+
+	sortedWidths := append([]int(nil), widths...)
+	sort.Ints(sortedWidths)
+	bitWidth := sortedWidths[(len(sortedWidths)*9)/10]
+	if bitWidth == 0 {
+		// A bit width of 0 would let Decompress read an arbitrary number of
+		// values without consuming any packed bytes, decoupling decode cost
+		// from payload size. Every other codec in this series ties decode
+		// cost to bytes actually read, so keep this one in line by never
+		// going below 1: the (now more than 0) non-fitting deltas just fall
+		// back to the exception list, same as any other out-of-range delta.
+		bitWidth = 1
+	}
+	out = append(out, byte(bitWidth))
+
+	mask := uint64(1)<<uint(bitWidth) - 1
+
+	var exceptions []byte
+	var numExceptions uint64
+	packed := newBitWriter()
+	for i, d := range deltas {
+		if uint64(d) > mask {
+			numExceptions++
+			var idxBuf, valBuf [binary.MaxVarintLen64]byte
+			ni := binary.PutUvarint(idxBuf[:], uint64(i))
+			nv := binary.PutUvarint(valBuf[:], uint64(d))
+			exceptions = append(exceptions, idxBuf[:ni]...)
+			exceptions = append(exceptions, valBuf[:nv]...)
+			packed.writeBits(0, bitWidth)
+		} else {
+			packed.writeBits(uint64(d), bitWidth)
+		}
+	}
+
+	ne := binary.PutUvarint(tmp[:], numExceptions)
+	out = append(out, tmp[:ne]...)
+	out = append(out, exceptions...)
+	out = append(out, packed.bytes()...)
+	return out
+}
+
+// Estimate computes the block's min/max and the bit width that would cover
+// every delta, without bit-packing or patching exceptions.
+func (frameOfReferenceCodec) Estimate(data []uint32) float64 {
+	if len(data) == 0 {
+		return 1.0
+	}
+	min, max := data[0], data[0]
+	for _, v := range data {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	bitWidth := bitsNeeded(max - min)
+	estBits := bitWidth * len(data)
+	estBytes := 5 + (estBits+7)/8
+	return float64(len(data)*4) / float64(estBytes)
+}
+
+func (frameOfReferenceCodec) Decompress(data []byte) ([]uint32, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("fpf: malformed count varint")
+	}
+	offset := n
+	if count == 0 {
+		return []uint32{}, nil
+	}
+	if count > maxDecodedValues {
+		return nil, fmt.Errorf("fpf: declared count %d exceeds the maximum of %d", count, maxDecodedValues)
+	}
+
+	if offset+4 > len(data) {
+		return nil, fmt.Errorf("fpf: truncated header")
+	}
+	min := binary.LittleEndian.Uint32(data[offset : offset+4])
+	offset += 4
+	if offset >= len(data) {
+		return nil, fmt.Errorf("fpf: missing bit width byte")
+	}
+	bitWidth := int(data[offset])
+	offset++
+	if bitWidth > 32 {
+		return nil, fmt.Errorf("fpf: invalid bit width %d", bitWidth)
+	}
+
+	numExceptions, n := binary.Uvarint(data[offset:])
+	if n <= 0 {
+		return nil, fmt.Errorf("fpf: malformed exception count varint")
+	}
+	offset += n
+
+	exceptions := make(map[uint64]uint32, numExceptions)
+	for i := uint64(0); i < numExceptions; i++ {
+		idx, n := binary.Uvarint(data[offset:])
+		if n <= 0 {
+			return nil, fmt.Errorf("fpf: malformed exception index")
+		}
+		offset += n
+		val, n := binary.Uvarint(data[offset:])
+		if n <= 0 {
+			return nil, fmt.Errorf("fpf: malformed exception value")
+		}
+		offset += n
+		exceptions[idx] = uint32(val)
+	}
+
+	// Bound count against the packed payload that's actually left. A
+	// legitimate Compress never emits bitWidth 0 (see the comment there),
+	// so treat it as 1 here too: otherwise a crafted payload could claim
+	// bitWidth 0 and need zero packed bytes for any count, letting the
+	// loop below run count times off the declared count alone and reach
+	// maxDecodedValues for free.
+	effectiveWidth := bitWidth
+	if effectiveWidth == 0 {
+		effectiveWidth = 1
+	}
+	neededBytes := (uint64(effectiveWidth)*count + 7) / 8
+	if neededBytes > uint64(len(data)-offset) {
+		return nil, fmt.Errorf("fpf: declared count %d at bit width %d needs %d packed bytes, only %d remain", count, bitWidth, neededBytes, len(data)-offset)
+	}
+
+	reader := newBitReader(data[offset:])
+	result := make([]uint32, 0, clampCap(count, 1<<20))
+	for i := uint64(0); i < count; i++ {
+		d, err := reader.readBits(bitWidth)
+		if err != nil {
+			return nil, fmt.Errorf("fpf: %w", err)
+		}
+		if val, ok := exceptions[i]; ok {
+			d = uint64(val)
+		}
+		sum := uint64(min) + d
+		if sum > uint64(^uint32(0)) {
+			return nil, fmt.Errorf("fpf: decoded value overflows uint32")
+		}
+		result = append(result, uint32(sum))
+	}
+	return result, nil
+}
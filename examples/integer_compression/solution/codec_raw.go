@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const codecRawID = 0
+
+func init() {
+	RegisterCodec(codecRawID, "raw", rawCodec{})
+}
+
+// rawCodec is the original baseline: every uint32 serialized little-endian,
+// back to back, with no compression at all.
+type rawCodec struct{}
+
+func (rawCodec) Compress(data []uint32) []byte {
+	result := make([]byte, len(data)*4)
+	for i, val := range data {
+		binary.LittleEndian.PutUint32(result[i*4:(i+1)*4], val)
+	}
+	return result
+}
+
+// Estimate is always 1.0: raw never compresses.
+func (rawCodec) Estimate(data []uint32) float64 {
+	return 1.0
+}
+
+func (rawCodec) Decompress(data []byte) ([]uint32, error) {
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("raw: payload length %d is not a multiple of 4", len(data))
+	}
+	result := make([]uint32, len(data)/4)
+	for i := range result {
+		result[i] = binary.LittleEndian.Uint32(data[i*4 : (i+1)*4])
+	}
+	return result, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const codecSimple8bID = 3
+
+func init() {
+	RegisterCodec(codecSimple8bID, "simple8b", simple8bCodec{})
+}
+
+// simple8bSelectors describes, for each 4-bit selector value, how many
+// integers a 64-bit word holds and how many bits each one occupies. This is
+// the table from Anh & Moffat's Simple8b scheme, ordered from most values
+// per word (all zero) to fewest (a single 60-bit value).
+var simple8bSelectors = [16]struct {
+	n    int
+	bits int
+}{
+	{240, 0}, {120, 0}, {60, 1}, {30, 2},
+	{20, 3}, {15, 4}, {12, 5}, {10, 6},
+	{8, 7}, {7, 8}, {6, 10}, {5, 12},
+	{4, 15}, {3, 20}, {2, 30}, {1, 60},
+}
+
+// simple8bCodec packs runs of small values into 64-bit words, choosing per
+// word the selector that fits the most values for their bit width.
+type simple8bCodec struct{}
+
+func simple8bFits(values []uint32, n, bits int) bool {
+	if len(values) < n {
+		return false
+	}
+	if bits == 0 {
+		for i := 0; i < n; i++ {
+			if values[i] != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	max := uint64(1)<<uint(bits) - 1
+	for i := 0; i < n; i++ {
+		if uint64(values[i]) > max {
+			return false
+		}
+	}
+	return true
+}
+
+func (simple8bCodec) Compress(data []uint32) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	out := make([]byte, 0, len(data))
+	cn := binary.PutUvarint(tmp[:], uint64(len(data)))
+	out = append(out, tmp[:cn]...)
+
+	var wordBuf [8]byte
+	i := 0
+	for i < len(data) {
+		sel := len(simple8bSelectors) - 1
+		for s, entry := range simple8bSelectors {
+			if simple8bFits(data[i:], entry.n, entry.bits) {
+				sel = s
+				break
+			}
+		}
+		n, bits := simple8bSelectors[sel].n, simple8bSelectors[sel].bits
+
+		word := uint64(sel)
+		for j := 0; j < n; j++ {
+			word |= uint64(data[i+j]) << uint(4+j*bits)
+		}
+		binary.LittleEndian.PutUint64(wordBuf[:], word)
+		out = append(out, wordBuf[:]...)
+		i += n
+	}
+	return out
+}
+
+// Estimate sums, for each value, the per-value bit cost of the narrowest
+// selector able to hold it — i.e. it counts the run-length of small values
+// without actually grouping them into words.
+func (simple8bCodec) Estimate(data []uint32) float64 {
+	if len(data) == 0 {
+		return 1.0
+	}
+	var totalBits int
+	for _, v := range data {
+		totalBits += simple8bBitsFor(bitsNeeded(v))
+	}
+	estBytes := (totalBits + 7) / 8
+	return float64(len(data)*4) / float64(estBytes)
+}
+
+// simple8bBitsFor returns the per-value bit cost of the narrowest selector
+// whose width can hold a value needing the given number of bits.
+func simple8bBitsFor(bitsNeeded int) int {
+	if bitsNeeded == 0 {
+		return 1
+	}
+	for _, s := range simple8bSelectors {
+		if s.bits >= bitsNeeded {
+			return s.bits
+		}
+	}
+	return 60
+}
+
+func (simple8bCodec) Decompress(data []byte) ([]uint32, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("simple8b: malformed count varint")
+	}
+	offset := n
+	result := make([]uint32, 0, clampCap(count, 1<<20))
+
+	for uint64(len(result)) < count {
+		if offset+8 > len(data) {
+			return nil, fmt.Errorf("simple8b: truncated word at offset %d", offset)
+		}
+		word := binary.LittleEndian.Uint64(data[offset : offset+8])
+		offset += 8
+
+		sel := word & 0xF
+		if int(sel) >= len(simple8bSelectors) {
+			return nil, fmt.Errorf("simple8b: invalid selector %d", sel)
+		}
+		nVals, bits := simple8bSelectors[sel].n, simple8bSelectors[sel].bits
+		mask := uint64(0)
+		if bits > 0 {
+			mask = uint64(1)<<uint(bits) - 1
+		}
+		for j := 0; j < nVals && uint64(len(result)) < count; j++ {
+			v := (word >> uint(4+j*bits)) & mask
+			result = append(result, uint32(v))
+		}
+	}
+	if offset != len(data) {
+		return nil, fmt.Errorf("simple8b: %d trailing bytes", len(data)-offset)
+	}
+	return result, nil
+}
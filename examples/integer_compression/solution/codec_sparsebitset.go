@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"sort"
+)
+
+const codecSparseBitsetID = 5
+
+// maxSparseWords bounds the number of populated 64-bit words a sparse
+// bitset payload may declare, so a corrupt or malicious declared count
+// can't force an oversized allocation. It covers the full uint32 range
+// (2^32 / 64), which is also the largest a genuine word index can be.
+const maxSparseWords = 1 << 26
+
+var (
+	errSparseMissingData    = errors.New("sparse-bitset: missing bytes")
+	errSparseTrailingData   = errors.New("sparse-bitset: trailing bytes")
+	errSparseLengthExceeded = errors.New("sparse-bitset: declared length exceeds target")
+	errSparseZeroWord       = errors.New("sparse-bitset: stored word is zero")
+)
+
+func init() {
+	RegisterCodec(codecSparseBitsetID, "sparse-bitset", sparseBitsetCodec{})
+}
+
+// sparseBitsetCodec is aimed at sets of sorted, unique uint32 IDs (posting
+// lists, bloom-adjacent workloads). It partitions the uint32 range into
+// 64-bit words and emits only the populated words, each tagged with its
+// word index delta-encoded against the previous one, so cost tracks the
+// number of populated words rather than the magnitude of the values
+// themselves.
+type sparseBitsetCodec struct{}
+
+func (sparseBitsetCodec) Compress(data []uint32) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	if len(data) == 0 {
+		n := binary.PutUvarint(tmp[:], 0)
+		return append([]byte(nil), tmp[:n]...)
+	}
+
+	wordVals := make(map[uint32]uint64, len(data))
+	for _, v := range data {
+		wordVals[v/64] |= 1 << uint(v%64)
+	}
+
+	words := make([]uint32, 0, len(wordVals))
+	for w := range wordVals {
+		words = append(words, w)
+	}
+	sort.Slice(words, func(i, j int) bool { return words[i] < words[j] })
+
+	var distinct uint64
+	for _, w := range words {
+		distinct += uint64(bits.OnesCount64(wordVals[w]))
+	}
+
+	// The declared count is the number of distinct values the set actually
+	// holds, not len(data): duplicates in data collapse to the same bit.
+	out := make([]byte, 0, len(words)*9+2*binary.MaxVarintLen64)
+	n := binary.PutUvarint(tmp[:], distinct)
+	out = append(out, tmp[:n]...)
+
+	nw := binary.PutUvarint(tmp[:], uint64(len(words)))
+	out = append(out, tmp[:nw]...)
+
+	var prev uint32
+	for i, w := range words {
+		delta := w
+		if i > 0 {
+			delta = w - prev
+		}
+		dn := binary.PutUvarint(tmp[:], uint64(delta))
+		out = append(out, tmp[:dn]...)
+		prev = w
+	}
+	for _, w := range words {
+		var wb [8]byte
+		binary.LittleEndian.PutUint64(wb[:], wordVals[w])
+		out = append(out, wb[:]...)
+	}
+	return out
+}
+
+// Estimate counts the populated words directly from data, the same way
+// Compress does, without building the delta-encoded index list.
+func (sparseBitsetCodec) Estimate(data []uint32) float64 {
+	if len(data) == 0 {
+		return 1.0
+	}
+	seen := make(map[uint32]struct{}, len(data))
+	for _, v := range data {
+		seen[v/64] = struct{}{}
+	}
+	estBytes := len(seen) * (8 + 2) // 8-byte word + a couple of index bytes
+	return float64(len(data)*4) / float64(estBytes)
+}
+
+func (sparseBitsetCodec) Decompress(data []byte) ([]uint32, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, errSparseMissingData
+	}
+	offset := n
+	if count == 0 {
+		if offset != len(data) {
+			return nil, errSparseTrailingData
+		}
+		return []uint32{}, nil
+	}
+
+	numWords, n := binary.Uvarint(data[offset:])
+	if n <= 0 {
+		return nil, errSparseMissingData
+	}
+	offset += n
+	if numWords > maxSparseWords || count > numWords*64 {
+		return nil, errSparseLengthExceeded
+	}
+
+	words := make([]uint32, 0, clampCap(numWords, 1<<20))
+	var prev uint64
+	for i := uint64(0); i < numWords; i++ {
+		delta, n := binary.Uvarint(data[offset:])
+		if n <= 0 {
+			return nil, errSparseMissingData
+		}
+		offset += n
+
+		idx := delta
+		if i > 0 {
+			idx = prev + delta
+		}
+		if idx >= maxSparseWords {
+			return nil, errSparseLengthExceeded
+		}
+		words = append(words, uint32(idx))
+		prev = idx
+	}
+
+	result := make([]uint32, 0, clampCap(count, 1<<20))
+	for _, idx := range words {
+		if offset+8 > len(data) {
+			return nil, errSparseMissingData
+		}
+		w := binary.LittleEndian.Uint64(data[offset : offset+8])
+		offset += 8
+		if w == 0 {
+			return nil, errSparseZeroWord
+		}
+		base := idx * 64
+		for b := 0; b < 64; b++ {
+			if w&(1<<uint(b)) != 0 {
+				result = append(result, base+uint32(b))
+			}
+		}
+	}
+	if offset != len(data) {
+		return nil, errSparseTrailingData
+	}
+	if uint64(len(result)) != count {
+		return nil, errSparseLengthExceeded
+	}
+	return result, nil
+}
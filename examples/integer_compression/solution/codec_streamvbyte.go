@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const codecStreamVByteID = 4
+
+func init() {
+	RegisterCodec(codecStreamVByteID, "streamvbyte", streamVByteCodec{})
+}
+
+// streamVByteCodec implements Lemire & Kurz's StreamVByte layout: a stream
+// of 2-bit length codes (four per control byte) followed by the variable
+// length value bytes themselves, kept in a separate stream so the data stays
+// SIMD-friendly.
+type streamVByteCodec struct{}
+
+func svbLen(v uint32) int {
+	switch {
+	case v < 1<<8:
+		return 1
+	case v < 1<<16:
+		return 2
+	case v < 1<<24:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func (streamVByteCodec) Compress(data []uint32) []byte {
+	numControl := (len(data) + 3) / 4
+	control := make([]byte, numControl)
+	var dataBytes []byte
+
+	for i, v := range data {
+		l := svbLen(v)
+		control[i/4] |= byte(l-1) << uint((i%4)*2)
+		for b := 0; b < l; b++ {
+			dataBytes = append(dataBytes, byte(v>>uint(8*b)))
+		}
+	}
+
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(len(data)))
+	out := make([]byte, 0, n+len(control)+len(dataBytes))
+	out = append(out, tmp[:n]...)
+	out = append(out, control...)
+	out = append(out, dataBytes...)
+	return out
+}
+
+// Estimate sums svbLen across data to predict the data stream size, adding
+// the fixed one-byte-per-four-values control stream cost.
+func (streamVByteCodec) Estimate(data []uint32) float64 {
+	if len(data) == 0 {
+		return 1.0
+	}
+	var total int
+	for _, v := range data {
+		total += svbLen(v)
+	}
+	numControl := (len(data) + 3) / 4
+	return float64(len(data)*4) / float64(numControl+total)
+}
+
+func (streamVByteCodec) Decompress(data []byte) ([]uint32, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("streamvbyte: malformed count varint")
+	}
+	offset := n
+
+	if count > uint64(len(data))*4 {
+		return nil, fmt.Errorf("streamvbyte: declared count %d exceeds payload size", count)
+	}
+	numControl := (int(count) + 3) / 4
+	if offset+numControl > len(data) {
+		return nil, fmt.Errorf("streamvbyte: truncated control stream")
+	}
+	control := data[offset : offset+numControl]
+	offset += numControl
+
+	result := make([]uint32, 0, clampCap(count, 1<<20))
+	for i := uint64(0); i < count; i++ {
+		l := int((control[i/4]>>uint((i%4)*2))&0x3) + 1
+		if offset+l > len(data) {
+			return nil, fmt.Errorf("streamvbyte: truncated data stream at value %d", i)
+		}
+		var v uint32
+		for b := 0; b < l; b++ {
+			v |= uint32(data[offset+b]) << uint(8*b)
+		}
+		offset += l
+		result = append(result, v)
+	}
+	if offset != len(data) {
+		return nil, fmt.Errorf("streamvbyte: %d trailing bytes", len(data)-offset)
+	}
+	return result, nil
+}
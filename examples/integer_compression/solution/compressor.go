@@ -1,27 +1,31 @@
 package main
 
-import (
-	"encoding/binary"
-)
+import "fmt"
 
-func Compress(data []uint32) []byte {
-	// Simple baseline: no compression, just serialize as-is
-	result := make([]byte, len(data)*4)
-
-	for i, val := range data {
-		binary.LittleEndian.PutUint32(result[i*4:(i+1)*4], val)
+// Compress encodes data with the named codec and prefixes the result with a
+// one-byte header identifying which codec produced it, so Decompress can
+// dispatch on read without the caller passing the name back in.
+func Compress(data []uint32, codec string) ([]byte, error) {
+	c, ok := CodecByName(codec)
+	if !ok {
+		return nil, fmt.Errorf("optiverse: unknown codec %q", codec)
 	}
-
-	return result
+	payload := c.Compress(data)
+	out := make([]byte, 1+len(payload))
+	out[0] = codecsByName[codec]
+	copy(out[1:], payload)
+	return out, nil
 }
 
-func Decompress(compressed []byte) []uint32 {
-	// Simple baseline: just deserialize the data
-	result := make([]uint32, len(compressed)/4)
-
-	for i := 0; i < len(result); i++ {
-		result[i] = binary.LittleEndian.Uint32(compressed[i*4:(i+1)*4])
+// Decompress reads the header byte written by Compress and dispatches to the
+// matching registered codec.
+func Decompress(data []byte) ([]uint32, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("optiverse: empty payload")
 	}
-
-	return result
+	c, ok := codecsByID[data[0]]
+	if !ok {
+		return nil, fmt.Errorf("optiverse: unknown codec id %d", data[0])
+	}
+	return c.Decompress(data[1:])
 }
@@ -0,0 +1,52 @@
+package main
+
+import "sort"
+
+// Estimate predicts, for every registered codec, the compression ratio it
+// would achieve on data without actually running full compression, by
+// calling each codec's cheap Estimate method.
+func Estimate(data []uint32) map[string]float64 {
+	out := make(map[string]float64, len(codecsByName))
+	for name, id := range codecsByName {
+		out[name] = codecsByID[id].Estimate(data)
+	}
+	return out
+}
+
+// autoSelectExcluded lists codecs bestCodec must never choose on the
+// caller's behalf, because they don't promise a bit-exact round trip for
+// arbitrary uint32 sequences the way every other registered codec does.
+// sparse-bitset represents a *set*: duplicate values collapse into the
+// same bit, so Decompress can return fewer values than were written. A
+// caller who knows their data is already a sorted, unique ID set can still
+// pick it explicitly by name via NewWriter.
+var autoSelectExcluded = map[string]bool{
+	"sparse-bitset": true,
+}
+
+// bestCodec returns the name of the registered codec with the highest
+// estimated ratio that clears threshold, or fallback if none do. It never
+// even computes an estimate for a codec listed in autoSelectExcluded,
+// rather than calling Estimate for every codec and throwing away the
+// excluded ones' results afterward. Ties are broken by codec name so the
+// choice is deterministic.
+func bestCodec(data []uint32, threshold float64, fallback string) string {
+	names := make([]string, 0, len(codecsByName))
+	for name := range codecsByName {
+		if autoSelectExcluded[name] {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best := fallback
+	bestRatio := threshold
+	for _, name := range names {
+		if r := codecsByID[codecsByName[name]].Estimate(data); r >= bestRatio {
+			best = name
+			bestRatio = r
+		}
+	}
+	return best
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -46,19 +48,48 @@ func calculateOriginalSize(data []uint32) int {
 	return len(data) * 4 // 4 bytes per uint32
 }
 
+// printEstimates prints each registered codec's predicted compression ratio
+// for data, without actually running full compression.
+func printEstimates(data []uint32) {
+	ratios := Estimate(data)
+	for _, name := range registeredCodecNames() {
+		fmt.Printf("%s: %.3f\n", name, ratios[name])
+	}
+}
+
+// registeredCodecNames returns the names of all registered codecs, sorted,
+// for use in flag usage strings.
+func registeredCodecNames() []string {
+	names := make([]string, 0, len(codecsByName))
+	for name := range codecsByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <test_data_file>\n", os.Args[0])
+	codec := flag.String("codec", "raw", "codec to benchmark (available: "+strings.Join(registeredCodecNames(), ", ")+")")
+	estimate := flag.Bool("estimate", false, "print each codec's estimated compression ratio for the input and exit")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-codec=<name>] [-estimate] <test_data_file>\n", os.Args[0])
 		os.Exit(1)
 	}
 
 	// Load test data
-	data, err := loadTestData(os.Args[1])
+	data, err := loadTestData(flag.Arg(0))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading data: %v\n", err)
 		os.Exit(1)
 	}
 
+	if *estimate {
+		printEstimates(data)
+		return
+	}
+
 	originalSize := calculateOriginalSize(data)
 
 	// Run multiple times and collect metrics
@@ -69,13 +100,21 @@ func main() {
 	for run := 0; run < numRuns; run++ {
 		// Measure compression
 		start := time.Now()
-		compressed := Compress(data)
+		compressed, err := Compress(data, *codec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error compressing: %v\n", err)
+			os.Exit(1)
+		}
 		compressionTime := time.Since(start)
 		compressedSize := len(compressed)
 
 		// Measure decompression
 		start = time.Now()
-		decompressed := Decompress(compressed)
+		decompressed, err := Decompress(compressed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error decompressing: %v\n", err)
+			os.Exit(1)
+		}
 		decompressionTime := time.Since(start)
 
 		// Verify correctness
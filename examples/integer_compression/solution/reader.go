@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sync"
+)
+
+// maxBlockPayloadLen bounds a block's declared payload length before it's
+// used as a slice allocation size. A compressed block this large is already
+// far beyond anything Writer would ever produce, so a declared length past
+// it can only be a corrupt or malicious stream.
+const maxBlockPayloadLen = 1 << 30
+
+// frame is one block's header and payload as read off the wire, before
+// decompression.
+type frame struct {
+	values  int
+	payload []byte
+	crc     uint32
+}
+
+// Reader reads a stream written by Writer, decompressing blocks across a
+// pool of workers and reassembling them into their original order.
+type Reader struct {
+	br      *bufio.Reader
+	workers int
+}
+
+// NewReader returns a Reader that decompresses blocks from r across
+// workers goroutines. workers falls back to 1 when <= 0.
+func NewReader(r io.Reader, workers int) *Reader {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Reader{br: bufio.NewReader(r), workers: workers}
+}
+
+// ReadAll reads every block frame up to the end-of-frames marker, verifies
+// each block's CRC32, decompresses the blocks across the worker pool, and
+// returns the reassembled values in their original order. It stops before
+// the trailing block index; use ReadBlockIndex and ReadBlockAt against an
+// io.ReaderAt to seek directly to a block instead.
+func (rd *Reader) ReadAll() ([]uint32, error) {
+	frames, err := rd.readFrames()
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := make([][]uint32, len(frames))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var firstErr error
+
+	for w := 0; w < rd.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				values, err := decodeFrame(frames[idx], idx)
+				if err != nil {
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMu.Unlock()
+					continue
+				}
+				decoded[idx] = values
+			}
+		}()
+	}
+	for i := range frames {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	var total int
+	for _, v := range decoded {
+		total += len(v)
+	}
+	result := make([]uint32, 0, total)
+	for _, v := range decoded {
+		result = append(result, v...)
+	}
+	return result, nil
+}
+
+// readFrames reads block frames sequentially until the end-of-frames
+// marker (a blockLen of 0).
+func (rd *Reader) readFrames() ([]frame, error) {
+	var frames []frame
+	for {
+		blockLen, err := binary.ReadUvarint(rd.br)
+		if err != nil {
+			return nil, fmt.Errorf("optiverse: reading block header: %w", err)
+		}
+		if blockLen == 0 {
+			return frames, nil
+		}
+		if blockLen > maxDecodedValues {
+			return nil, fmt.Errorf("optiverse: declared block length %d exceeds the maximum of %d", blockLen, maxDecodedValues)
+		}
+
+		payloadLen, err := binary.ReadUvarint(rd.br)
+		if err != nil {
+			return nil, fmt.Errorf("optiverse: reading payload length: %w", err)
+		}
+		if payloadLen > maxBlockPayloadLen {
+			return nil, fmt.Errorf("optiverse: declared payload length %d exceeds the maximum of %d", payloadLen, maxBlockPayloadLen)
+		}
+		payload := make([]byte, payloadLen)
+		if _, err := io.ReadFull(rd.br, payload); err != nil {
+			return nil, fmt.Errorf("optiverse: reading payload: %w", err)
+		}
+		var crcBuf [4]byte
+		if _, err := io.ReadFull(rd.br, crcBuf[:]); err != nil {
+			return nil, fmt.Errorf("optiverse: reading crc32: %w", err)
+		}
+		frames = append(frames, frame{
+			values:  int(blockLen),
+			payload: payload,
+			crc:     binary.LittleEndian.Uint32(crcBuf[:]),
+		})
+	}
+}
+
+// decodeFrame verifies a frame's CRC32, decompresses it, and checks that the
+// decoded length matches what the frame header declared.
+func decodeFrame(f frame, idx int) ([]uint32, error) {
+	if crc32.ChecksumIEEE(f.payload) != f.crc {
+		return nil, fmt.Errorf("optiverse: block %d failed crc32 check", idx)
+	}
+	values, err := Decompress(f.payload)
+	if err != nil {
+		return nil, fmt.Errorf("optiverse: block %d: %w", idx, err)
+	}
+	if len(values) != f.values {
+		return nil, fmt.Errorf("optiverse: block %d decoded %d values, expected %d", idx, len(values), f.values)
+	}
+	return values, nil
+}
+
+// ReadBlockIndex reads the trailing offset table written by Writer.Close,
+// given the total size of the stream, so a caller can seek directly to any
+// block with ReadBlockAt instead of reading the whole stream sequentially.
+func ReadBlockIndex(ra io.ReaderAt, size int64) ([]int64, error) {
+	if size < 16 {
+		return nil, fmt.Errorf("optiverse: stream too short to contain a trailer")
+	}
+	var trailer [16]byte
+	if _, err := ra.ReadAt(trailer[:], size-16); err != nil {
+		return nil, fmt.Errorf("optiverse: reading trailer: %w", err)
+	}
+	indexOffset := int64(binary.LittleEndian.Uint64(trailer[0:8]))
+	indexLen := int64(binary.LittleEndian.Uint64(trailer[8:16]))
+	if indexOffset < 0 || indexLen < 0 || indexOffset+indexLen > size-16 {
+		return nil, fmt.Errorf("optiverse: corrupt trailer")
+	}
+
+	buf := make([]byte, indexLen)
+	if _, err := ra.ReadAt(buf, indexOffset); err != nil {
+		return nil, fmt.Errorf("optiverse: reading block index: %w", err)
+	}
+	count, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return nil, fmt.Errorf("optiverse: malformed block index count")
+	}
+	buf = buf[n:]
+
+	offsets := make([]int64, 0, clampCap(count, 1<<20))
+	for i := uint64(0); i < count; i++ {
+		off, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("optiverse: malformed block index entry %d", i)
+		}
+		offsets = append(offsets, int64(off))
+		buf = buf[n:]
+	}
+	return offsets, nil
+}
+
+// ReadBlockAt decompresses the single block starting at offset within ra,
+// as located via ReadBlockIndex, verifying its CRC32 along the way.
+func ReadBlockAt(ra io.ReaderAt, offset int64) ([]uint32, error) {
+	br := bufio.NewReader(io.NewSectionReader(ra, offset, 1<<40))
+
+	blockLen, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("optiverse: reading block header: %w", err)
+	}
+	if blockLen > maxDecodedValues {
+		return nil, fmt.Errorf("optiverse: declared block length %d exceeds the maximum of %d", blockLen, maxDecodedValues)
+	}
+	payloadLen, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("optiverse: reading payload length: %w", err)
+	}
+	if payloadLen > maxBlockPayloadLen {
+		return nil, fmt.Errorf("optiverse: declared payload length %d exceeds the maximum of %d", payloadLen, maxBlockPayloadLen)
+	}
+	payload := make([]byte, payloadLen)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		return nil, fmt.Errorf("optiverse: reading payload: %w", err)
+	}
+	var crcBuf [4]byte
+	if _, err := io.ReadFull(br, crcBuf[:]); err != nil {
+		return nil, fmt.Errorf("optiverse: reading crc32: %w", err)
+	}
+
+	return decodeFrame(frame{
+		values:  int(blockLen),
+		payload: payload,
+		crc:     binary.LittleEndian.Uint32(crcBuf[:]),
+	}, 0)
+}
@@ -0,0 +1,65 @@
+package main
+
+import "fmt"
+
+// Codec compresses and decompresses a list of uint32 values. Implementations
+// register themselves with RegisterCodec so the benchmark harness and the
+// streaming Reader/Writer can pick one another by name or by the magic byte
+// written into a compressed payload.
+type Codec interface {
+	// Compress encodes data into a self-contained byte payload.
+	Compress(data []uint32) []byte
+	// Decompress decodes a payload previously produced by Compress.
+	Decompress(data []byte) ([]uint32, error)
+	// Estimate predicts, in O(n) without actually compressing data, the
+	// ratio Compress would achieve on it (originalBytes / compressedBytes).
+	Estimate(data []uint32) float64
+}
+
+var (
+	codecsByID   = map[byte]Codec{}
+	codecsByName = map[string]byte{}
+)
+
+// RegisterCodec makes a codec available under name, tagging every payload it
+// produces with id so Decompress can dispatch on it without the caller
+// having to remember which codec compressed it. It panics on a duplicate id
+// or name, the same way database/sql drivers register themselves.
+func RegisterCodec(id byte, name string, c Codec) {
+	if _, ok := codecsByID[id]; ok {
+		panic(fmt.Sprintf("optiverse: codec id %d already registered", id))
+	}
+	if _, ok := codecsByName[name]; ok {
+		panic(fmt.Sprintf("optiverse: codec %q already registered", name))
+	}
+	codecsByID[id] = c
+	codecsByName[name] = id
+}
+
+// CodecByName looks up a registered codec by its flag-friendly name.
+func CodecByName(name string) (Codec, bool) {
+	id, ok := codecsByName[name]
+	if !ok {
+		return nil, false
+	}
+	return codecsByID[id], true
+}
+
+// maxDecodedValues bounds a declared element count read from a compressed
+// payload. Some codecs can encode a run of many values in very few bytes
+// (e.g. frame-of-reference with bit width 0 for all-zero deltas), so a
+// cheap byte-length check alone can't catch a payload that declares an
+// absurd count to force a very long decode loop.
+const maxDecodedValues = 1 << 28
+
+// clampCap bounds a length field read from a compressed payload before it's
+// used as a slice preallocation hint, so a corrupt or malicious payload that
+// declares billions of elements can't force a multi-gigabyte allocation.
+// append still grows the slice correctly past max; this only affects the
+// initial capacity.
+func clampCap(n uint64, max int) int {
+	if n > uint64(max) {
+		return max
+	}
+	return int(n)
+}
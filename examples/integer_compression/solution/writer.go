@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"sync"
+)
+
+// defaultBlockSize is the number of uint32s per block when the caller
+// doesn't specify one.
+const defaultBlockSize = 1 << 16
+
+// compressedBlock is the result of compressing one block, along with any
+// error Compress returned for it.
+type compressedBlock struct {
+	payload []byte
+	values  int
+	err     error
+}
+
+// job is one block of values queued for a worker to compress, paired with
+// the channel its result is delivered on.
+type job struct {
+	values []uint32
+	result chan compressedBlock
+}
+
+// Writer splits a sequence of uint32s into fixed-size blocks and compresses
+// them on a pool of workers as they fill up, writing each block's
+// [blockLen uvarint][payloadLen uvarint][payload][crc32] frame to the
+// underlying io.Writer in order as soon as it's ready -- rather than
+// buffering the whole input -- so a caller can stream data far larger than
+// memory through it. Only the block offset table, which is O(#blocks), is
+// held until Close. A Writer is not safe for concurrent use: Write and
+// Close must be called from a single goroutine, the same way an io.Writer
+// normally would be.
+type Writer struct {
+	w         io.Writer
+	codec     string
+	blockSize int
+
+	autoSelect bool
+	threshold  float64
+
+	pending []uint32
+
+	jobs  chan job
+	order chan chan compressedBlock
+
+	workersDone chan struct{}
+	writerDone  chan struct{}
+
+	offsets  []int64
+	offset   int64
+	firstErr error
+}
+
+// NewWriter returns a Writer that compresses data with the named codec in
+// blocks of blockSize uint32s, using workers goroutines to compress blocks
+// concurrently. blockSize and workers both fall back to sane defaults when
+// <= 0.
+func NewWriter(w io.Writer, codec string, blockSize, workers int) *Writer {
+	if blockSize <= 0 {
+		blockSize = defaultBlockSize
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+
+	// Depth bounds how many blocks may be in flight (queued, compressing,
+	// or compressed-but-not-yet-written) at once, so memory use stays
+	// proportional to the worker pool rather than the whole input.
+	depth := workers * 2
+	wr := &Writer{
+		w:           w,
+		codec:       codec,
+		blockSize:   blockSize,
+		jobs:        make(chan job, depth),
+		order:       make(chan chan compressedBlock, depth),
+		workersDone: make(chan struct{}),
+		writerDone:  make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wr.compressJobs()
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(wr.workersDone)
+	}()
+	go wr.drainResults()
+
+	return wr
+}
+
+// NewAutoWriter behaves like NewWriter, except it chooses the codec
+// per block via Estimate, picking the cheapest codec whose predicted ratio
+// clears threshold and falling back to fallback when none do.
+func NewAutoWriter(w io.Writer, fallback string, threshold float64, blockSize, workers int) *Writer {
+	wr := NewWriter(w, fallback, blockSize, workers)
+	wr.autoSelect = true
+	wr.threshold = threshold
+	return wr
+}
+
+// Write queues data for compression, submitting each blockSize chunk to the
+// worker pool as soon as it fills up. A final, possibly short, block is
+// submitted by Close.
+func (wr *Writer) Write(data []uint32) {
+	wr.pending = append(wr.pending, data...)
+	for len(wr.pending) >= wr.blockSize {
+		wr.submit(append([]uint32(nil), wr.pending[:wr.blockSize]...))
+		wr.pending = wr.pending[wr.blockSize:]
+	}
+}
+
+// submit hands values to the worker pool and records the order it should
+// be written back out in, blocking if depth blocks are already in flight.
+func (wr *Writer) submit(values []uint32) {
+	resCh := make(chan compressedBlock, 1)
+	wr.order <- resCh
+	wr.jobs <- job{values: values, result: resCh}
+}
+
+// Close submits any remaining partial block, waits for every queued block
+// to be compressed and written in order, then appends the block index and
+// a fixed-size trailer pointing at it.
+func (wr *Writer) Close() error {
+	if len(wr.pending) > 0 {
+		wr.submit(wr.pending)
+		wr.pending = nil
+	}
+
+	close(wr.jobs)
+	<-wr.workersDone
+	close(wr.order)
+	<-wr.writerDone
+
+	if wr.firstErr != nil {
+		return wr.firstErr
+	}
+
+	// A zero blockLen marks the end of the frame stream, so a sequential
+	// Reader knows to stop before the block index that follows it.
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], 0)
+	if _, err := wr.w.Write(tmp[:n]); err != nil {
+		return err
+	}
+
+	return wr.writeIndex(wr.offsets, wr.offset+int64(n))
+}
+
+// compressJobs runs queued blocks through the configured codec until jobs
+// is closed and drained, delivering each result on its own result channel.
+func (wr *Writer) compressJobs() {
+	for j := range wr.jobs {
+		codec := wr.codec
+		if wr.autoSelect {
+			codec = bestCodec(j.values, wr.threshold, wr.codec)
+		}
+		payload, err := Compress(j.values, codec)
+		j.result <- compressedBlock{payload: payload, values: len(j.values), err: err}
+	}
+}
+
+// drainResults writes each block's frame in submission order as its result
+// becomes available, regardless of the order workers finish compressing
+// them in. It's the sole owner of offsets/offset/firstErr, so no locking is
+// needed. Once firstErr is set it stops writing but keeps draining order so
+// submit never blocks waiting on a reader that's gone quiet.
+func (wr *Writer) drainResults() {
+	defer close(wr.writerDone)
+	var tmp [binary.MaxVarintLen64]byte
+	for resCh := range wr.order {
+		r := <-resCh
+		if wr.firstErr != nil {
+			continue
+		}
+		if r.err != nil {
+			wr.firstErr = r.err
+			continue
+		}
+		n, err := wr.writeFrame(r, &tmp)
+		if err != nil {
+			wr.firstErr = err
+			continue
+		}
+		wr.offsets = append(wr.offsets, wr.offset)
+		wr.offset += int64(n)
+	}
+}
+
+// writeFrame writes one block's frame and returns its total byte length.
+func (wr *Writer) writeFrame(r compressedBlock, tmp *[binary.MaxVarintLen64]byte) (int, error) {
+	n1 := binary.PutUvarint(tmp[:], uint64(r.values))
+	if _, err := wr.w.Write(tmp[:n1]); err != nil {
+		return 0, err
+	}
+	n2 := binary.PutUvarint(tmp[:], uint64(len(r.payload)))
+	if _, err := wr.w.Write(tmp[:n2]); err != nil {
+		return 0, err
+	}
+	if _, err := wr.w.Write(r.payload); err != nil {
+		return 0, err
+	}
+	var crcBuf [4]byte
+	binary.LittleEndian.PutUint32(crcBuf[:], crc32.ChecksumIEEE(r.payload))
+	if _, err := wr.w.Write(crcBuf[:]); err != nil {
+		return 0, err
+	}
+	return n1 + n2 + len(r.payload) + 4, nil
+}
+
+// writeIndex appends the block offset table, followed by a fixed 16-byte
+// trailer ([indexOffset][indexLen], both little-endian uint64) so a later
+// reader can find the index from the end of the stream without scanning
+// every frame.
+func (wr *Writer) writeIndex(offsets []int64, indexOffset int64) error {
+	var buf []byte
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], uint64(len(offsets)))
+	buf = append(buf, tmp[:n]...)
+	for _, off := range offsets {
+		n := binary.PutUvarint(tmp[:], uint64(off))
+		buf = append(buf, tmp[:n]...)
+	}
+	if _, err := wr.w.Write(buf); err != nil {
+		return err
+	}
+
+	var trailer [16]byte
+	binary.LittleEndian.PutUint64(trailer[0:8], uint64(indexOffset))
+	binary.LittleEndian.PutUint64(trailer[8:16], uint64(len(buf)))
+	_, err := wr.w.Write(trailer[:])
+	return err
+}